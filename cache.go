@@ -1,8 +1,11 @@
 package cache
 
 import (
+	"context"
+	"io"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -33,12 +36,69 @@ type Cache[K comparable, V any] interface {
 	CleanExpired()
 	// Close stops the cleanup routine and closes the cache object.
 	Close()
+	// GetOrLoad returns the cached value for key, or calls loader to produce
+	// one if it's missing or expired. Concurrent misses for the same key are
+	// coalesced so loader runs exactly once; all callers get its result.
+	// loader's returned duration is the TTL to store the value with (0 = the
+	// cache's defaultTTL, <0 = no expiration).
+	GetOrLoad(key K, loader func(K) (V, time.Duration, error)) (V, error)
+	// GetOrLoadCtx is GetOrLoad with a context; a cancelled ctx makes the
+	// caller stop waiting and return ctx.Err(), without affecting the
+	// in-flight loader or other waiters.
+	GetOrLoadCtx(ctx context.Context, key K, loader func(context.Context, K) (V, time.Duration, error)) (V, error)
+	// Save writes the cache's current contents to w so a later process can
+	// restore them with Load/NewFrom.
+	Save(w io.Writer) error
+	// Load reads a dump written by Save/SaveFile into the cache, adding to
+	// (not replacing) whatever is already present.
+	Load(r io.Reader) error
+	// SaveFile is Save into the file at path, creating or truncating it.
+	SaveFile(path string) error
+	// LoadFile is Load from the file at path.
+	LoadFile(path string) error
+	// Stats returns a snapshot of the cache's hit/miss/write/eviction counters.
+	Stats() CacheStats
 	// resetItems
 	resetItems()
 }
 
+// Option configures a cache created by New.
+type Option[K comparable, V any] func(*cache[K, V])
+
+// WithNegativeCacheTTL makes GetOrLoad/GetOrLoadCtx cache a loader error for
+// d, so concurrent and near-future misses for the same key are shielded
+// from a failing upstream instead of re-running the loader immediately.
+func WithNegativeCacheTTL[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.sf.negativeCacheTTL = d
+	}
+}
+
+// WithOnEvicted registers a callback invoked whenever an item leaves the
+// cache through Remove, Clear, TTL expiration or size-limit eviction, or is
+// overwritten by a new Set. It is called after the internal lock is released,
+// so it is safe for the callback to call back into the cache.
+func WithOnEvicted[K comparable, V any](fn func(K, V, Reason)) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.onEvicted = fn
+	}
+}
+
+// WithOnExpired registers a callback invoked whenever CleanExpired drops an
+// item for having passed its TTL. It is called after the internal lock is
+// released, in addition to (not instead of) any WithOnEvicted callback.
+func WithOnExpired[K comparable, V any](fn func(K, V)) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.onExpired = fn
+	}
+}
+
 // New returns a new cache object that can store key-value pairs of any comparable key type and any value type.
 //
+// When maxSize > 0, the item evicted to make room is an arbitrary resident
+// key, not the least-recently-used one - cheap, but surprising under load.
+// Use NewLRU instead if callers need real LRU eviction order.
+//
 // defaultTTL - default duration after which the values will expire (<=0 for no expiration)
 //
 // cleanupInterval - cleanup interval for expired values (<=0 wihout cleanup)
@@ -48,12 +108,17 @@ func New[K comparable, V any](
 	defaultTTL time.Duration,
 	cleanupInterval time.Duration,
 	maxSize int,
+	opts ...Option[K, V],
 ) Cache[K, V] {
 	c := &cache[K, V]{
 		items:      make(map[K]*item[V]),
 		defaultTTL: defaultTTL,
 	}
 
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	var wrapped Cache[K, V]
 	if maxSize > 0 {
 		//obal pro omezovani velikosti pri zapisu
@@ -118,8 +183,17 @@ type cache[K comparable, V any] struct {
 	defaultTTL time.Duration
 	running    bool
 	done       chan struct{}
+	sf         singleflightGroup[K, V]
+	onEvicted  func(K, V, Reason)
+	onExpired  func(K, V)
+
+	hits, misses, sets int64
+	evictions          [reasonCount]int64
 }
 
+// cacheWithSize caps the number of resident entries by deleting an arbitrary
+// map key on overflow, not the least-recently-used one - see NewLRU for a
+// Cache[K,V] with real LRU eviction order.
 type cacheWithSize[K comparable, V any] struct {
 	*cache[K, V]
 	maxSize int
@@ -133,9 +207,11 @@ func (c *cache[K, V]) Get(key K) (V, bool) {
 	c.mu.RLock()
 	if item, found := c.items[key]; found {
 		c.mu.RUnlock()
+		atomic.AddInt64(&c.hits, 1)
 		return item.value, true
 	}
 	c.mu.RUnlock()
+	atomic.AddInt64(&c.misses, 1)
 	return *new(V), false
 }
 
@@ -144,10 +220,12 @@ func (c *cache[K, V]) GetSafe(key K) (V, bool) {
 	if item, found := c.items[key]; found {
 		if !item.isExpired(time.Now().UnixNano()) {
 			c.mu.RUnlock()
+			atomic.AddInt64(&c.hits, 1)
 			return item.value, true
 		}
 	}
 	c.mu.RUnlock()
+	atomic.AddInt64(&c.misses, 1)
 	return *new(V), false
 }
 
@@ -163,8 +241,16 @@ func (c *cache[K, V]) GetValue(key K) V {
 
 func (c *cache[K, V]) Remove(key K) {
 	c.mu.Lock()
+	it, found := c.items[key]
 	delete(c.items, key)
+	if found {
+		atomic.AddInt64(&c.evictions[ReasonManual], 1)
+	}
 	c.mu.Unlock()
+
+	if found && c.onEvicted != nil {
+		c.onEvicted(key, it.value, ReasonManual)
+	}
 }
 
 func (c *cache[K, V]) IsEmpty() bool {
@@ -192,10 +278,43 @@ func (c *cache[K, V]) CleanExpired() {
 	c.mu.RUnlock()
 
 	c.mu.Lock()
+	expired := make([]*item[V], 0, len(keys))
 	for _, key := range keys {
+		expired = append(expired, c.items[key])
 		delete(c.items, key)
 	}
+	atomic.AddInt64(&c.evictions[ReasonExpired], int64(len(keys)))
 	c.mu.Unlock()
+
+	if c.onEvicted != nil {
+		for i, key := range keys {
+			c.onEvicted(key, expired[i].value, ReasonExpired)
+		}
+	}
+	if c.onExpired != nil {
+		for i, key := range keys {
+			c.onExpired(key, expired[i].value)
+		}
+	}
+}
+
+// Stats returns a snapshot of the cache's hit/miss/write/eviction counters.
+func (c *cache[K, V]) Stats() CacheStats {
+	c.mu.RLock()
+	size := len(c.items)
+	c.mu.RUnlock()
+
+	var evictions [reasonCount]int64
+	for r := range evictions {
+		evictions[r] = atomic.LoadInt64(&c.evictions[r])
+	}
+	return CacheStats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Sets:      atomic.LoadInt64(&c.sets),
+		Size:      size,
+		Evictions: evictionsMap(evictions),
+	}
 }
 
 func (c *cache[K, V]) Close() {
@@ -211,8 +330,11 @@ func (c *cache[K, V]) resetItems() {
 }
 func (c *cacheWithoutSize[K, V]) Clear() {
 	c.mu.Lock()
+	cleared := c.takeAllForClear()
 	c.resetItems()
 	c.mu.Unlock()
+
+	c.fireCleared(cleared)
 }
 
 func (c *cacheWithSize[K, V]) resetItems() {
@@ -220,8 +342,44 @@ func (c *cacheWithSize[K, V]) resetItems() {
 }
 func (c *cacheWithSize[K, V]) Clear() {
 	c.mu.Lock()
+	cleared := c.takeAllForClear()
 	c.resetItems()
 	c.mu.Unlock()
+
+	c.fireCleared(cleared)
+}
+
+// clearedItem is the (key, value) pair snapshotted by takeAllForClear before
+// Clear wipes the map, so onEvicted can be called after c.mu is released.
+type clearedItem[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// takeAllForClear snapshots every item still in the cache for the onEvicted
+// callback. Caller holds c.mu. Returns nil if there's no callback to feed.
+func (c *cache[K, V]) takeAllForClear() []clearedItem[K, V] {
+	if c.onEvicted == nil {
+		atomic.AddInt64(&c.evictions[ReasonManual], int64(len(c.items)))
+		return nil
+	}
+	cleared := make([]clearedItem[K, V], 0, len(c.items))
+	for key, it := range c.items {
+		cleared = append(cleared, clearedItem[K, V]{key, it.value})
+	}
+	atomic.AddInt64(&c.evictions[ReasonManual], int64(len(cleared)))
+	return cleared
+}
+
+// fireCleared invokes onEvicted for every item captured by takeAllForClear.
+// Must be called after c.mu is released.
+func (c *cache[K, V]) fireCleared(cleared []clearedItem[K, V]) {
+	if c.onEvicted == nil {
+		return
+	}
+	for _, it := range cleared {
+		c.onEvicted(it.key, it.value, ReasonManual)
+	}
 }
 
 // Set bez kontroly velikosti
@@ -238,12 +396,21 @@ func (c *cacheWithoutSize[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
 
 	c.mu.Lock()
 
+	replaced, found := c.items[key]
 	c.items[key] = &item[V]{
 		value:      value,
 		expiration: expiration,
 	}
+	atomic.AddInt64(&c.sets, 1)
+	if found {
+		atomic.AddInt64(&c.evictions[ReasonReplaced], 1)
+	}
 
 	c.mu.Unlock()
+
+	if found && c.onEvicted != nil {
+		c.onEvicted(key, replaced.value, ReasonReplaced)
+	}
 }
 
 // SetAll bez kontroly velikosti
@@ -260,14 +427,31 @@ func (c *cacheWithoutSize[K, V]) SetAllWithTTL(m map[K]V, ttl time.Duration) {
 
 	c.mu.Lock()
 
+	var replaced []clearedItem[K, V]
+	if c.onEvicted != nil {
+		replaced = make([]clearedItem[K, V], 0, len(m))
+	}
 	for key, value := range m {
+		if old, found := c.items[key]; found {
+			atomic.AddInt64(&c.evictions[ReasonReplaced], 1)
+			if c.onEvicted != nil {
+				replaced = append(replaced, clearedItem[K, V]{key, old.value})
+			}
+		}
 		c.items[key] = &item[V]{
 			value:      value,
 			expiration: expiration,
 		}
 	}
+	atomic.AddInt64(&c.sets, int64(len(m)))
 
 	c.mu.Unlock()
+
+	if c.onEvicted != nil {
+		for _, it := range replaced {
+			c.onEvicted(it.key, it.value, ReasonReplaced)
+		}
+	}
 }
 
 // Set s kontrolou velikosti
@@ -284,16 +468,19 @@ func (c *cacheWithSize[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
 
 	c.mu.Lock()
 
+	replaced, found := c.items[key]
+
 	//omezeni velikosti
-	if len(c.items)+1 > c.maxSize {
-		if _, found := c.items[key]; !found {
-			//smaze prvni klic v mape, je to nefer:)
-			//chtel sem vybrat nahodne pred reflect.ValueOf(c.items).MapKeys() ale to je pomale
-			//neco jako linked hash map se mi delat nechce
-			for k := range c.items {
-				delete(c.items, k)
-				break
-			}
+	var evictedKey K
+	var evicted *item[V]
+	if len(c.items)+1 > c.maxSize && !found {
+		//smaze prvni klic v mape, je to nefer:)
+		//chtel sem vybrat nahodne pred reflect.ValueOf(c.items).MapKeys() ale to je pomale
+		//neco jako linked hash map se mi delat nechce
+		for k := range c.items {
+			evictedKey, evicted = k, c.items[k]
+			delete(c.items, k)
+			break
 		}
 	}
 
@@ -301,8 +488,25 @@ func (c *cacheWithSize[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
 		value:      value,
 		expiration: expiration,
 	}
+	atomic.AddInt64(&c.sets, 1)
+	if found {
+		atomic.AddInt64(&c.evictions[ReasonReplaced], 1)
+	}
+	if evicted != nil {
+		atomic.AddInt64(&c.evictions[ReasonCapacity], 1)
+	}
 
 	c.mu.Unlock()
+
+	if c.onEvicted == nil {
+		return
+	}
+	if found {
+		c.onEvicted(key, replaced.value, ReasonReplaced)
+	}
+	if evicted != nil {
+		c.onEvicted(evictedKey, evicted.value, ReasonCapacity)
+	}
 }
 
 // SetAll s kontrolou velikosti
@@ -319,32 +523,104 @@ func (c *cacheWithSize[K, V]) SetAllWithTTL(m map[K]V, ttl time.Duration) {
 
 	c.mu.Lock()
 
+	var evicted, replaced []clearedItem[K, V]
+
 	//omezeni velikosti
 	if newSize := len(c.items) + len(m); newSize > c.maxSize {
 		//smaze x nahodnych polozek
 		x := newSize - c.maxSize
+		var evictedCount int64
 		if len(c.items) <= x {
 			//smaze vsechno a rovnou pripravi prostor pro nove polozky
+			evictedCount = int64(len(c.items))
+			if c.onEvicted != nil {
+				evicted = make([]clearedItem[K, V], 0, len(c.items))
+				for k, it := range c.items {
+					evicted = append(evicted, clearedItem[K, V]{k, it.value})
+				}
+			}
 			c.items = make(map[K]*item[V], len(m))
 		} else {
 			//smaze x polozek
 			i := 0
-			for k := range c.items {
+			for k, it := range c.items {
+				if c.onEvicted != nil {
+					evicted = append(evicted, clearedItem[K, V]{k, it.value})
+				}
 				delete(c.items, k)
 				i++
+				evictedCount++
 				if i >= x || len(c.items) == 0 {
 					break
 				}
 			}
 		}
+		atomic.AddInt64(&c.evictions[ReasonCapacity], evictedCount)
 	}
 
 	for key, value := range m {
+		if old, found := c.items[key]; found {
+			atomic.AddInt64(&c.evictions[ReasonReplaced], 1)
+			if c.onEvicted != nil {
+				replaced = append(replaced, clearedItem[K, V]{key, old.value})
+			}
+		}
 		c.items[key] = &item[V]{
 			value:      value,
 			expiration: expiration,
 		}
 	}
+	atomic.AddInt64(&c.sets, int64(len(m)))
 
 	c.mu.Unlock()
+
+	if c.onEvicted == nil {
+		return
+	}
+	for _, it := range evicted {
+		c.onEvicted(it.key, it.value, ReasonCapacity)
+	}
+	for _, it := range replaced {
+		c.onEvicted(it.key, it.value, ReasonReplaced)
+	}
+}
+
+// GetOrLoad bez kontroly velikosti
+func (c *cacheWithoutSize[K, V]) GetOrLoad(key K, loader func(K) (V, time.Duration, error)) (V, error) {
+	return c.sf.getOrLoad(key, loader, c.GetSafe, c.storeWithTTL)
+}
+
+// GetOrLoadCtx bez kontroly velikosti
+func (c *cacheWithoutSize[K, V]) GetOrLoadCtx(ctx context.Context, key K, loader func(context.Context, K) (V, time.Duration, error)) (V, error) {
+	return c.sf.getOrLoadCtx(ctx, key, loader, c.GetSafe, c.storeWithTTL)
+}
+
+// storeWithTTL writes a GetOrLoad result: ttl == 0 means "use the cache's
+// defaultTTL", matching the convention documented on the interface.
+func (c *cacheWithoutSize[K, V]) storeWithTTL(key K, value V, ttl time.Duration) {
+	if ttl == 0 {
+		c.Set(key, value)
+		return
+	}
+	c.SetWithTTL(key, value, ttl)
+}
+
+// GetOrLoad s kontrolou velikosti
+func (c *cacheWithSize[K, V]) GetOrLoad(key K, loader func(K) (V, time.Duration, error)) (V, error) {
+	return c.sf.getOrLoad(key, loader, c.GetSafe, c.storeWithTTL)
+}
+
+// GetOrLoadCtx s kontrolou velikosti
+func (c *cacheWithSize[K, V]) GetOrLoadCtx(ctx context.Context, key K, loader func(context.Context, K) (V, time.Duration, error)) (V, error) {
+	return c.sf.getOrLoadCtx(ctx, key, loader, c.GetSafe, c.storeWithTTL)
+}
+
+// storeWithTTL writes a GetOrLoad result: ttl == 0 means "use the cache's
+// defaultTTL", matching the convention documented on the interface.
+func (c *cacheWithSize[K, V]) storeWithTTL(key K, value V, ttl time.Duration) {
+	if ttl == 0 {
+		c.Set(key, value)
+		return
+	}
+	c.SetWithTTL(key, value, ttl)
 }
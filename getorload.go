@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// call represents an in-flight or completed loader invocation shared by all
+// callers that asked for the same key at the same time.
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+}
+
+type negativeEntry struct {
+	err        error
+	expiration int64
+}
+
+// singleflightGroup coalesces concurrent GetOrLoad misses for the same key
+// so the loader runs exactly once, and optionally caches loader errors for
+// a short time so a failing upstream isn't hammered by every miss.
+type singleflightGroup[K comparable, V any] struct {
+	mu               sync.Mutex
+	calls            map[K]*call[V]
+	negative         map[K]negativeEntry
+	negativeCacheTTL time.Duration
+}
+
+// getOrLoad runs the shared load-once-per-key logic. get and store plug in
+// the owning Cache[K,V]'s own lookup/insert so each cache flavour keeps its
+// own eviction and size-limiting behaviour.
+func (g *singleflightGroup[K, V]) getOrLoad(
+	key K,
+	loader func(K) (V, time.Duration, error),
+	get func(K) (V, bool),
+	store func(key K, value V, ttl time.Duration),
+) (V, error) {
+	if value, found := get(key); found {
+		return value, nil
+	}
+
+	g.mu.Lock()
+	if err, found := g.takeNegative(key); found {
+		g.mu.Unlock()
+		return *new(V), err
+	}
+	if c, found := g.calls[key]; found {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err
+	}
+
+	c := g.startCall(key)
+	g.mu.Unlock()
+
+	value, ttl, err := loader(key)
+	g.finishCall(key, c, value, ttl, err, store)
+
+	return value, err
+}
+
+// getOrLoadCtx is the context-aware twin of getOrLoad: it still lets the
+// loader run to completion for other waiters, but a caller whose ctx is
+// cancelled stops waiting and gets ctx.Err() instead of the loader's result.
+func (g *singleflightGroup[K, V]) getOrLoadCtx(
+	ctx context.Context,
+	key K,
+	loader func(context.Context, K) (V, time.Duration, error),
+	get func(K) (V, bool),
+	store func(key K, value V, ttl time.Duration),
+) (V, error) {
+	if value, found := get(key); found {
+		return value, nil
+	}
+
+	g.mu.Lock()
+	if err, found := g.takeNegative(key); found {
+		g.mu.Unlock()
+		return *new(V), err
+	}
+	if c, found := g.calls[key]; found {
+		g.mu.Unlock()
+		if err := waitCtx(ctx, &c.wg); err != nil {
+			return *new(V), err
+		}
+		return c.value, c.err
+	}
+
+	c := g.startCall(key)
+	g.mu.Unlock()
+
+	//loader bezi odpojeny od zavolajiciho ctx - jinak by leaderovo zruseni/timeout
+	//predcasne ukoncilo load i pro vsechny ostatni cekajici. Leader sam na vysledek
+	//cti ceka pres waitCtx stejne jako kazdy dalsi caller, takze jeho vlastni ctx
+	//porad funguje pro to, aby prestal cekat.
+	go func() {
+		value, ttl, err := loader(context.Background(), key)
+		g.finishCall(key, c, value, ttl, err, store)
+	}()
+
+	if err := waitCtx(ctx, &c.wg); err != nil {
+		return *new(V), err
+	}
+	return c.value, c.err
+}
+
+// startCall registers an in-flight call for key. Caller holds g.mu.
+func (g *singleflightGroup[K, V]) startCall(key K) *call[V] {
+	c := &call[V]{}
+	c.wg.Add(1)
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	g.calls[key] = c
+	return c
+}
+
+// finishCall records the loader's result, stores it on success, negative-caches
+// it on failure, and wakes any goroutines waiting on c.
+func (g *singleflightGroup[K, V]) finishCall(
+	key K,
+	c *call[V],
+	value V,
+	ttl time.Duration,
+	err error,
+	store func(key K, value V, ttl time.Duration),
+) {
+	c.value, c.err = value, err
+
+	//hodnotu ulozime jeste pred smazanim z g.calls - jinak by novy caller v mezere
+	//nenasel ani hodnotu v cache, ani rozbehnuty call, a spustil loader znovu
+	if err == nil {
+		store(key, value, ttl)
+	}
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	if err != nil && g.negativeCacheTTL > 0 {
+		if g.negative == nil {
+			g.negative = make(map[K]negativeEntry)
+		}
+		g.negative[key] = negativeEntry{err: err, expiration: time.Now().Add(g.negativeCacheTTL).UnixNano()}
+	}
+	g.mu.Unlock()
+
+	c.wg.Done()
+}
+
+// takeNegative returns the cached error for key if it is still within its
+// negative-cache TTL, removing it once it has expired. Caller holds g.mu.
+func (g *singleflightGroup[K, V]) takeNegative(key K) (error, bool) {
+	entry, found := g.negative[key]
+	if !found {
+		return nil, false
+	}
+	if time.Now().UnixNano() > entry.expiration {
+		delete(g.negative, key)
+		return nil, false
+	}
+	return entry.err, true
+}
+
+// waitCtx blocks until wg is done or ctx is cancelled, whichever comes first.
+func waitCtx(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
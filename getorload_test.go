@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	c := New[string, int](0, 0, 0)
+
+	var calls int32
+	loader := func(key string) (int, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return len(key), 0, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := c.GetOrLoad("hello", loader)
+			if err != nil || val != 5 {
+				t.Errorf("GetOrLoad() = %v, %v, want 5, nil", val, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("loader called %v times, want 1", calls)
+	}
+
+	if val, err := c.GetOrLoad("hello", loader); err != nil || val != 5 {
+		t.Errorf("GetOrLoad() after caching = %v, %v, want 5, nil", val, err)
+	}
+	if calls != 1 {
+		t.Errorf("loader called %v times, want 1", calls)
+	}
+}
+
+func TestGetOrLoadNegativeCache(t *testing.T) {
+	c := New[string, int](0, 0, 0, WithNegativeCacheTTL[string, int](100*time.Millisecond))
+
+	var calls int32
+	errLoad := errors.New("upstream down")
+	loader := func(key string) (int, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, 0, errLoad
+	}
+
+	if _, err := c.GetOrLoad("key", loader); !errors.Is(err, errLoad) {
+		t.Errorf("GetOrLoad() err = %v, want %v", err, errLoad)
+	}
+	if _, err := c.GetOrLoad("key", loader); !errors.Is(err, errLoad) {
+		t.Errorf("GetOrLoad() err = %v, want %v", err, errLoad)
+	}
+	if calls != 1 {
+		t.Errorf("loader called %v times while negative-cached, want 1", calls)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if _, err := c.GetOrLoad("key", loader); !errors.Is(err, errLoad) {
+		t.Errorf("GetOrLoad() err = %v, want %v", err, errLoad)
+	}
+	if calls != 2 {
+		t.Errorf("loader called %v times after negative-cache expiry, want 2", calls)
+	}
+}
+
+func TestGetOrLoadCtxCancel(t *testing.T) {
+	c := New[string, int](0, 0, 0)
+
+	unblock := make(chan struct{})
+	loader := func(key string) (int, time.Duration, error) {
+		<-unblock
+		return 1, 0, nil
+	}
+
+	go func() {
+		_, _ = c.GetOrLoad("key", loader)
+	}()
+	time.Sleep(20 * time.Millisecond) //necham prvni volani startnout loader
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := c.GetOrLoadCtx(ctx, "key", func(ctx context.Context, key string) (int, time.Duration, error) {
+		return loader(key)
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetOrLoadCtx() err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(unblock)
+}
+
+// TestGetOrLoadCtxLeaderCancelDoesNotPoisonFollowers covers the leader
+// itself timing out: its ctx must not abort the shared loader call, so
+// every other waiter - leader included, were it not for its own timeout -
+// still gets the loader's real result instead of the leader's ctx.Err().
+func TestGetOrLoadCtxLeaderCancelDoesNotPoisonFollowers(t *testing.T) {
+	c := New[string, int](0, 0, 0)
+
+	unblock := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, time.Duration, error) {
+		<-unblock
+		return 42, 0, nil
+	}
+
+	leaderCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var leaderErr error
+	leaderDone := make(chan struct{})
+	go func() {
+		_, leaderErr = c.GetOrLoadCtx(leaderCtx, "key", loader)
+		close(leaderDone)
+	}()
+	time.Sleep(5 * time.Millisecond) //necham leadera startnout loader a stat se leaderem
+
+	followerDone := make(chan struct{})
+	var followerVal int
+	var followerErr error
+	go func() {
+		followerVal, followerErr = c.GetOrLoadCtx(context.Background(), "key", loader)
+		close(followerDone)
+	}()
+
+	<-leaderDone
+	if !errors.Is(leaderErr, context.DeadlineExceeded) {
+		t.Errorf("leader GetOrLoadCtx() err = %v, want %v", leaderErr, context.DeadlineExceeded)
+	}
+
+	close(unblock)
+	<-followerDone
+	if followerErr != nil || followerVal != 42 {
+		t.Errorf("follower GetOrLoadCtx() = %v, %v, want 42, nil", followerVal, followerErr)
+	}
+}
@@ -0,0 +1,400 @@
+package cache
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// lruItem is a node of the doubly-linked list threaded through lruCache.items.
+type lruItem[K comparable, V any] struct {
+	key        K
+	value      V
+	expiration int64
+	prev, next *lruItem[K, V]
+}
+
+func (item *lruItem[K, V]) isExpired(now int64) bool {
+	if item.expiration == 0 {
+		return false
+	}
+	return now > item.expiration
+}
+
+// lruCache is a Cache[K,V] implementation that evicts the least-recently-used
+// entry in O(1) instead of an arbitrary map key. Everything - the map and the
+// prev/next pointers of the list - is guarded by a single mutex, since Get
+// also has to move the accessed node to the front.
+type lruCache[K comparable, V any] struct {
+	mu         sync.Mutex
+	items      map[K]*lruItem[K, V]
+	head, tail *lruItem[K, V] // head = most recently used, tail = least recently used
+	defaultTTL time.Duration
+	maxSize    int
+	running    bool
+	done       chan struct{}
+	onEvicted  func(K, V, Reason)
+	sf         singleflightGroup[K, V]
+
+	hits, misses, sets int64
+	evictions          [reasonCount]int64
+}
+
+// LRUOption configures a cache created by NewLRU.
+type LRUOption[K comparable, V any] func(*lruCache[K, V])
+
+// WithLRUOnEvicted registers a callback invoked whenever an item leaves the
+// cache through TTL expiration, capacity eviction, Remove or Clear; an
+// existing key overwritten by Set does not trigger it. It is called
+// after the internal lock is released, so it is safe for the callback to
+// call back into the cache.
+func WithLRUOnEvicted[K comparable, V any](fn func(K, V, Reason)) LRUOption[K, V] {
+	return func(c *lruCache[K, V]) {
+		c.onEvicted = fn
+	}
+}
+
+// NewLRU returns a new cache object that, once maxSize is reached, evicts
+// the least-recently-used entry in O(1) rather than an arbitrary map key.
+//
+// defaultTTL - default duration after which the values will expire (<=0 for no expiration)
+//
+// cleanupInterval - cleanup interval for expired values (<=0 wihout cleanup)
+//
+// maxSize - maximum number of entries (must be > 0)
+func NewLRU[K comparable, V any](
+	defaultTTL time.Duration,
+	cleanupInterval time.Duration,
+	maxSize int,
+	opts ...LRUOption[K, V],
+) Cache[K, V] {
+	c := &lruCache[K, V]{
+		defaultTTL: defaultTTL,
+		maxSize:    maxSize,
+	}
+	c.resetItems()
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	runtime.SetFinalizer(c, func(c *lruCache[K, V]) {
+		c.Close()
+	})
+
+	//nastartovat uklid
+	if cleanupInterval > 0 {
+		ticker := time.NewTicker(cleanupInterval)
+
+		c.running = true
+		c.done = make(chan struct{})
+
+		go func() {
+			for {
+				select {
+				case <-c.done:
+					ticker.Stop()
+					return
+				case <-ticker.C:
+					c.CleanExpired()
+				}
+			}
+		}()
+	}
+
+	return c
+}
+
+// unlink removes node from the list. Caller holds c.mu.
+func (c *lruCache[K, V]) unlink(node *lruItem[K, V]) {
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		c.head = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		c.tail = node.prev
+	}
+	node.prev, node.next = nil, nil
+}
+
+// pushFront inserts node as the most-recently-used entry. Caller holds c.mu.
+func (c *lruCache[K, V]) pushFront(node *lruItem[K, V]) {
+	node.prev = nil
+	node.next = c.head
+	if c.head != nil {
+		c.head.prev = node
+	}
+	c.head = node
+	if c.tail == nil {
+		c.tail = node
+	}
+}
+
+// moveToFront marks node as the most-recently-used entry. Caller holds c.mu.
+func (c *lruCache[K, V]) moveToFront(node *lruItem[K, V]) {
+	if c.head == node {
+		return
+	}
+	c.unlink(node)
+	c.pushFront(node)
+}
+
+// evictTail removes and returns the least-recently-used node, or nil if the
+// cache is empty. Caller holds c.mu.
+func (c *lruCache[K, V]) evictTail() *lruItem[K, V] {
+	node := c.tail
+	if node == nil {
+		return nil
+	}
+	c.unlink(node)
+	delete(c.items, node.key)
+	return node
+}
+
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	node, found := c.items[key]
+	if !found {
+		c.misses++
+		c.mu.Unlock()
+		return *new(V), false
+	}
+	c.hits++
+	c.moveToFront(node)
+	value := node.value
+	c.mu.Unlock()
+	return value, true
+}
+
+func (c *lruCache[K, V]) GetSafe(key K) (V, bool) {
+	c.mu.Lock()
+	node, found := c.items[key]
+	if !found || node.isExpired(time.Now().UnixNano()) {
+		c.misses++
+		c.mu.Unlock()
+		return *new(V), false
+	}
+	c.hits++
+	c.moveToFront(node)
+	value := node.value
+	c.mu.Unlock()
+	return value, true
+}
+
+func (c *lruCache[K, V]) GetValue(key K) V {
+	c.mu.Lock()
+	node, found := c.items[key]
+	if !found {
+		c.mu.Unlock()
+		return *new(V)
+	}
+	c.moveToFront(node)
+	value := node.value
+	c.mu.Unlock()
+	return value
+}
+
+func (c *lruCache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+func (c *lruCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	c.mu.Lock()
+
+	if node, found := c.items[key]; found {
+		//existujici klic se jen posune na zacatek, nepocita se do limitu znovu
+		node.value = value
+		node.expiration = expiration
+		c.moveToFront(node)
+		c.sets++
+		c.mu.Unlock()
+		return
+	}
+
+	var evicted *lruItem[K, V]
+	if len(c.items)+1 > c.maxSize {
+		evicted = c.evictTail()
+	}
+
+	node := &lruItem[K, V]{key: key, value: value, expiration: expiration}
+	c.items[key] = node
+	c.pushFront(node)
+	c.sets++
+	if evicted != nil {
+		c.evictions[ReasonCapacity]++
+	}
+
+	c.mu.Unlock()
+
+	if evicted != nil && c.onEvicted != nil {
+		c.onEvicted(evicted.key, evicted.value, ReasonCapacity)
+	}
+}
+
+func (c *lruCache[K, V]) SetAll(m map[K]V) {
+	c.SetAllWithTTL(m, c.defaultTTL)
+}
+
+func (c *lruCache[K, V]) SetAllWithTTL(m map[K]V, ttl time.Duration) {
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	c.mu.Lock()
+
+	var evicted []*lruItem[K, V]
+	for key, value := range m {
+		if node, found := c.items[key]; found {
+			node.value = value
+			node.expiration = expiration
+			c.moveToFront(node)
+			c.sets++
+			continue
+		}
+
+		if len(c.items)+1 > c.maxSize {
+			if victim := c.evictTail(); victim != nil {
+				evicted = append(evicted, victim)
+			}
+		}
+
+		node := &lruItem[K, V]{key: key, value: value, expiration: expiration}
+		c.items[key] = node
+		c.pushFront(node)
+		c.sets++
+	}
+	c.evictions[ReasonCapacity] += int64(len(evicted))
+
+	c.mu.Unlock()
+
+	if c.onEvicted != nil {
+		for _, node := range evicted {
+			c.onEvicted(node.key, node.value, ReasonCapacity)
+		}
+	}
+}
+
+func (c *lruCache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	node, found := c.items[key]
+	if found {
+		c.unlink(node)
+		delete(c.items, key)
+		c.evictions[ReasonManual]++
+	}
+	c.mu.Unlock()
+
+	if found && c.onEvicted != nil {
+		c.onEvicted(node.key, node.value, ReasonManual)
+	}
+}
+
+func (c *lruCache[K, V]) IsEmpty() bool {
+	return c.Size() == 0
+}
+
+func (c *lruCache[K, V]) Size() int {
+	c.mu.Lock()
+	size := len(c.items)
+	c.mu.Unlock()
+	return size
+}
+
+func (c *lruCache[K, V]) Clear() {
+	c.mu.Lock()
+	var cleared []*lruItem[K, V]
+	if c.onEvicted != nil {
+		cleared = make([]*lruItem[K, V], 0, len(c.items))
+		for _, node := range c.items {
+			cleared = append(cleared, node)
+		}
+	}
+	c.evictions[ReasonManual] += int64(len(c.items))
+	c.resetItems()
+	c.mu.Unlock()
+
+	if c.onEvicted != nil {
+		for _, node := range cleared {
+			c.onEvicted(node.key, node.value, ReasonManual)
+		}
+	}
+}
+
+func (c *lruCache[K, V]) CleanExpired() {
+	now := time.Now().UnixNano()
+
+	c.mu.Lock()
+	var expired []*lruItem[K, V]
+	for key, node := range c.items {
+		if node.isExpired(now) {
+			expired = append(expired, node)
+			delete(c.items, key)
+		}
+	}
+	for _, node := range expired {
+		c.unlink(node)
+	}
+	c.evictions[ReasonExpired] += int64(len(expired))
+	c.mu.Unlock()
+
+	if c.onEvicted != nil {
+		for _, node := range expired {
+			c.onEvicted(node.key, node.value, ReasonExpired)
+		}
+	}
+}
+
+// Stats returns a snapshot of the cache's hit/miss/write/eviction counters.
+func (c *lruCache[K, V]) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Sets:      c.sets,
+		Size:      len(c.items),
+		Evictions: evictionsMap(c.evictions),
+	}
+}
+
+func (c *lruCache[K, V]) Close() {
+	if c.running {
+		c.running = false
+		close(c.done)
+	}
+	c.items = make(map[K]*lruItem[K, V])
+	c.head, c.tail = nil, nil
+}
+
+func (c *lruCache[K, V]) resetItems() {
+	c.items = make(map[K]*lruItem[K, V], c.maxSize)
+	c.head, c.tail = nil, nil
+}
+
+func (c *lruCache[K, V]) GetOrLoad(key K, loader func(K) (V, time.Duration, error)) (V, error) {
+	return c.sf.getOrLoad(key, loader, c.GetSafe, c.storeWithTTL)
+}
+
+func (c *lruCache[K, V]) GetOrLoadCtx(ctx context.Context, key K, loader func(context.Context, K) (V, time.Duration, error)) (V, error) {
+	return c.sf.getOrLoadCtx(ctx, key, loader, c.GetSafe, c.storeWithTTL)
+}
+
+// storeWithTTL writes a GetOrLoad result: ttl == 0 means "use the cache's
+// defaultTTL", matching the convention documented on the interface.
+func (c *lruCache[K, V]) storeWithTTL(key K, value V, ttl time.Duration) {
+	if ttl == 0 {
+		c.Set(key, value)
+		return
+	}
+	c.SetWithTTL(key, value, ttl)
+}
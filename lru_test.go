@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU[string, string](0, 0, 2)
+
+	c.Set("key1", "val1")
+	c.Set("key2", "val2")
+
+	if size := c.Size(); size != 2 {
+		t.Errorf("Size() = %v, want %v", size, 2)
+	}
+
+	//sahnu na key1, takze key2 je nejdel nepouzity
+	if _, found := c.Get("key1"); !found {
+		t.Errorf("Get(key1) not found")
+	}
+
+	c.Set("key3", "val3")
+
+	if size := c.Size(); size != 2 {
+		t.Errorf("Size() = %v, want %v", size, 2)
+	}
+
+	//key2 mel byt evictnuty, key1 a key3 zustavaji
+	if _, found := c.Get("key2"); found {
+		t.Errorf("Get(key2) found, want evicted")
+	}
+	if val1, _ := c.Get("key1"); val1 != "val1" {
+		t.Errorf("Get(key1) = %v, want %v", val1, "val1")
+	}
+	if val3, _ := c.Get("key3"); val3 != "val3" {
+		t.Errorf("Get(key3) = %v, want %v", val3, "val3")
+	}
+}
+
+func TestLRUOnEvicted(t *testing.T) {
+	type evt struct {
+		key    string
+		reason Reason
+	}
+	var events []evt
+
+	c := NewLRU[string, string](50*time.Millisecond, 0, 1,
+		WithLRUOnEvicted[string, string](func(key string, _ string, reason Reason) {
+			events = append(events, evt{key, reason})
+		}),
+	)
+
+	c.Set("key1", "val1")
+	c.Set("key2", "val2")
+
+	if len(events) != 1 || events[0].key != "key1" || events[0].reason != ReasonCapacity {
+		t.Errorf("unexpected capacity eviction events: %+v", events)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	c.CleanExpired()
+
+	if len(events) != 2 || events[1].key != "key2" || events[1].reason != ReasonExpired {
+		t.Errorf("unexpected expiration events: %+v", events)
+	}
+}
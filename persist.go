@@ -0,0 +1,287 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ItemSnapshot is one entry of a cache dump produced by Save/SaveFile and
+// consumed by Load/LoadFile/NewFrom. Expiration is the absolute UnixNano
+// expiration time (0 = no expiration), so TTLs survive a restart.
+//
+// Because Go generics don't auto-register gob types, if V itself holds
+// interface values, callers must gob.Register the concrete types it can
+// hold before calling Save or Load.
+type ItemSnapshot[V any] struct {
+	Value      V
+	Expiration int64
+}
+
+// snapshotVersion is written ahead of the gob-encoded items so a future,
+// incompatible dump format can be told apart from this one.
+const snapshotVersion = 1
+
+type snapshotHeader struct {
+	Version int
+}
+
+func encodeSnapshot[K comparable, V any](w io.Writer, items map[K]ItemSnapshot[V]) error {
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion}); err != nil {
+		return err
+	}
+	return enc.Encode(items)
+}
+
+func decodeSnapshot[K comparable, V any](r io.Reader) (map[K]ItemSnapshot[V], error) {
+	dec := gob.NewDecoder(r)
+
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, err
+	}
+	if header.Version != snapshotVersion {
+		return nil, fmt.Errorf("cache: unsupported snapshot version %d", header.Version)
+	}
+
+	var items map[K]ItemSnapshot[V]
+	if err := dec.Decode(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// snapshotTTL converts snap's absolute expiration into a TTL suitable for
+// SetWithTTL; ok is false if the entry had already expired by now.
+func snapshotTTL[V any](snap ItemSnapshot[V], now int64) (ttl time.Duration, ok bool) {
+	if snap.Expiration == 0 {
+		return 0, true
+	}
+	ttl = time.Duration(snap.Expiration - now)
+	return ttl, ttl > 0
+}
+
+// loadSnapshot re-inserts items via setWithTTL - rather than writing the
+// target's internal map directly - so that each Cache[K,V] implementation's
+// own Set behaviour (size limits, LRU/admission bookkeeping, ...) still
+// applies. Already-expired entries are dropped.
+func loadSnapshot[K comparable, V any](items map[K]ItemSnapshot[V], setWithTTL func(K, V, time.Duration)) {
+	now := time.Now().UnixNano()
+	for key, snap := range items {
+		if ttl, ok := snapshotTTL(snap, now); ok {
+			setWithTTL(key, snap.Value, ttl)
+		}
+	}
+}
+
+// Save writes the cache's current contents to w using encoding/gob, behind
+// a small versioned header.
+func (c *cache[K, V]) Save(w io.Writer) error {
+	c.mu.RLock()
+	items := make(map[K]ItemSnapshot[V], len(c.items))
+	for key, it := range c.items {
+		items[key] = ItemSnapshot[V]{Value: it.value, Expiration: it.expiration}
+	}
+	c.mu.RUnlock()
+
+	return encodeSnapshot(w, items)
+}
+
+// SaveFile is Save into the file at path, creating or truncating it.
+func (c *cache[K, V]) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Save(f)
+}
+
+func (c *cacheWithoutSize[K, V]) Load(r io.Reader) error {
+	items, err := decodeSnapshot[K, V](r)
+	if err != nil {
+		return err
+	}
+	loadSnapshot(items, c.SetWithTTL)
+	return nil
+}
+
+func (c *cacheWithoutSize[K, V]) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
+
+func (c *cacheWithSize[K, V]) Load(r io.Reader) error {
+	items, err := decodeSnapshot[K, V](r)
+	if err != nil {
+		return err
+	}
+	loadSnapshot(items, c.SetWithTTL)
+	return nil
+}
+
+func (c *cacheWithSize[K, V]) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
+
+// NewFrom returns a new cache pre-populated with items, such as one produced
+// by a previous process's Save/SaveFile. It behaves exactly like New
+// otherwise; already-expired entries in items are dropped.
+//
+// defaultTTL - default duration after which the values will expire (<=0 for no expiration)
+//
+// cleanupInterval - cleanup interval for expired values (<=0 wihout cleanup)
+//
+// maxSize - maximum size (0 wihout limit)
+func NewFrom[K comparable, V any](
+	defaultTTL time.Duration,
+	cleanupInterval time.Duration,
+	maxSize int,
+	items map[K]ItemSnapshot[V],
+) Cache[K, V] {
+	c := New[K, V](defaultTTL, cleanupInterval, maxSize)
+	loadSnapshot(items, c.SetWithTTL)
+	return c
+}
+
+func (c *lruCache[K, V]) Save(w io.Writer) error {
+	c.mu.Lock()
+	items := make(map[K]ItemSnapshot[V], len(c.items))
+	for key, node := range c.items {
+		items[key] = ItemSnapshot[V]{Value: node.value, Expiration: node.expiration}
+	}
+	c.mu.Unlock()
+
+	return encodeSnapshot(w, items)
+}
+
+func (c *lruCache[K, V]) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Save(f)
+}
+
+func (c *lruCache[K, V]) Load(r io.Reader) error {
+	items, err := decodeSnapshot[K, V](r)
+	if err != nil {
+		return err
+	}
+	loadSnapshot(items, c.SetWithTTL)
+	return nil
+}
+
+func (c *lruCache[K, V]) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
+
+// Save merges every shard's contents into a single dump, so it loads back
+// with any shardCount and hasher, not just the ones it was saved with.
+func (c *shardedCache[K, V]) Save(w io.Writer) error {
+	merged := make(map[K]ItemSnapshot[V])
+	for _, shard := range c.shards {
+		var buf bytes.Buffer
+		if err := shard.Save(&buf); err != nil {
+			return err
+		}
+		items, err := decodeSnapshot[K, V](&buf)
+		if err != nil {
+			return err
+		}
+		for key, snap := range items {
+			merged[key] = snap
+		}
+	}
+	return encodeSnapshot(w, merged)
+}
+
+func (c *shardedCache[K, V]) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Save(f)
+}
+
+func (c *shardedCache[K, V]) Load(r io.Reader) error {
+	items, err := decodeSnapshot[K, V](r)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixNano()
+	for key, snap := range items {
+		if ttl, ok := snapshotTTL(snap, now); ok {
+			c.shardFor(key).SetWithTTL(key, snap.Value, ttl)
+		}
+	}
+	return nil
+}
+
+func (c *shardedCache[K, V]) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
+
+func (c *WeightedCache[K, V]) Save(w io.Writer) error {
+	c.mu.Lock()
+	items := make(map[K]ItemSnapshot[V], len(c.items))
+	for key, node := range c.items {
+		items[key] = ItemSnapshot[V]{Value: node.value, Expiration: node.expiration}
+	}
+	c.mu.Unlock()
+
+	return encodeSnapshot(w, items)
+}
+
+func (c *WeightedCache[K, V]) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Save(f)
+}
+
+func (c *WeightedCache[K, V]) Load(r io.Reader) error {
+	items, err := decodeSnapshot[K, V](r)
+	if err != nil {
+		return err
+	}
+	//cost se pri nacitani prepocita pres costFn, neni soucasti snapshotu
+	loadSnapshot(items, c.SetWithTTL)
+	return nil
+}
+
+func (c *WeightedCache[K, V]) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
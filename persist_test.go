@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	c := New[string, string](0, 0, 0)
+	c.Set("key1", "val1")
+	c.SetWithTTL("key2", "val2", time.Hour)
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	c2 := New[string, string](0, 0, 0)
+	if err := c2.Load(&buf); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if val, _ := c2.Get("key1"); val != "val1" {
+		t.Errorf("Get(key1) = %v, want %v", val, "val1")
+	}
+	if val, _ := c2.Get("key2"); val != "val2" {
+		t.Errorf("Get(key2) = %v, want %v", val, "val2")
+	}
+}
+
+func TestSaveLoadDropsExpired(t *testing.T) {
+	c := New[string, string](0, 0, 0)
+	c.SetWithTTL("stale", "val", 10*time.Millisecond)
+
+	var buf bytes.Buffer
+	time.Sleep(20 * time.Millisecond)
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	c2 := New[string, string](0, 0, 0)
+	if err := c2.Load(&buf); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	//uz expirovany klic se nenacte
+	if _, found := c2.Get("stale"); found {
+		t.Errorf("Get(stale) found, want dropped as already expired")
+	}
+}
+
+func TestSaveFileLoadFile(t *testing.T) {
+	c := New[string, string](0, 0, 0)
+	c.Set("key1", "val1")
+
+	path := t.TempDir() + "/cache.gob"
+	if err := c.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+
+	c2 := New[string, string](0, 0, 0)
+	if err := c2.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if val, _ := c2.Get("key1"); val != "val1" {
+		t.Errorf("Get(key1) = %v, want %v", val, "val1")
+	}
+}
+
+func TestNewFrom(t *testing.T) {
+	c := NewFrom[string, string](0, 0, 0, map[string]ItemSnapshot[string]{
+		"key1": {Value: "val1"},
+	})
+
+	if val, _ := c.Get("key1"); val != "val1" {
+		t.Errorf("Get(key1) = %v, want %v", val, "val1")
+	}
+}
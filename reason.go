@@ -0,0 +1,33 @@
+package cache
+
+// Reason describes why an entry left a cache.
+type Reason int
+
+const (
+	// ReasonExpired means the entry's TTL elapsed.
+	ReasonExpired Reason = iota
+	// ReasonCapacity means the entry was evicted to make room under a size or cost limit.
+	ReasonCapacity
+	// ReasonManual means the entry was removed by an explicit Remove or Clear call.
+	ReasonManual
+	// ReasonReplaced means a new Set overwrote the entry before it expired or was evicted.
+	ReasonReplaced
+
+	reasonCount // not a valid Reason, only used to size per-reason counter arrays
+)
+
+// CacheStats is a point-in-time snapshot of a cache's hit/miss/write/eviction
+// counters, as returned by Stats().
+type CacheStats struct {
+	Hits, Misses, Sets int64
+	Size               int
+	Evictions          map[Reason]int64
+}
+
+func evictionsMap(counters [reasonCount]int64) map[Reason]int64 {
+	m := make(map[Reason]int64, reasonCount)
+	for reason, count := range counters {
+		m[Reason(reason)] = count
+	}
+	return m
+}
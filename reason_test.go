@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheOnEvicted(t *testing.T) {
+	type evt struct {
+		key    string
+		reason Reason
+	}
+	var events []evt
+
+	c := New[string, string](0, 0, 1,
+		WithOnEvicted[string, string](func(key string, _ string, reason Reason) {
+			events = append(events, evt{key, reason})
+		}),
+	)
+
+	c.Set("key1", "val1")
+	c.Set("key1", "val1-b")
+	c.Set("key2", "val2")
+
+	if len(events) != 2 || events[0].reason != ReasonReplaced || events[1].reason != ReasonCapacity {
+		t.Errorf("unexpected eviction events after replace+capacity: %+v", events)
+	}
+
+	c.Remove("key2")
+	if len(events) != 3 || events[2].reason != ReasonManual {
+		t.Errorf("unexpected eviction events after Remove: %+v", events)
+	}
+}
+
+func TestCacheOnEvictedExpired(t *testing.T) {
+	type evt struct {
+		key    string
+		reason Reason
+	}
+	var events []evt
+
+	c := New[string, string](20*time.Millisecond, 0, 0,
+		WithOnEvicted[string, string](func(key string, _ string, reason Reason) {
+			events = append(events, evt{key, reason})
+		}),
+	)
+
+	c.Set("key1", "val1")
+	time.Sleep(40 * time.Millisecond)
+	c.CleanExpired()
+
+	if len(events) != 1 || events[0].key != "key1" || events[0].reason != ReasonExpired {
+		t.Errorf("unexpected eviction events after CleanExpired: %+v", events)
+	}
+}
+
+func TestCacheOnExpired(t *testing.T) {
+	var expiredKeys []string
+
+	c := New[string, string](20*time.Millisecond, 0, 0,
+		WithOnExpired[string, string](func(key string, _ string) {
+			expiredKeys = append(expiredKeys, key)
+		}),
+	)
+
+	c.Set("key1", "val1")
+	time.Sleep(40 * time.Millisecond)
+	c.CleanExpired()
+
+	if len(expiredKeys) != 1 || expiredKeys[0] != "key1" {
+		t.Errorf("expiredKeys = %v, want [key1]", expiredKeys)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	c := New[string, string](0, 0, 1)
+
+	c.Set("key1", "val1")
+	c.Get("key1")
+	c.Get("missing")
+	c.Set("key2", "val2") //vytlaci key1, maxSize je 1
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Sets != 2 {
+		t.Errorf("Stats() = %+v, want Hits=1 Misses=1 Sets=2", stats)
+	}
+	if stats.Size != 1 {
+		t.Errorf("Stats().Size = %v, want 1", stats.Size)
+	}
+	if stats.Evictions[ReasonCapacity] != 1 {
+		t.Errorf("Stats().Evictions[ReasonCapacity] = %v, want 1", stats.Evictions[ReasonCapacity])
+	}
+}
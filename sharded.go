@@ -0,0 +1,271 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"time"
+)
+
+// Hasher turns a key into a shard selector. The low bits of the returned
+// value are used, so any reasonably distributed hash works.
+type Hasher[K comparable] func(key K) uint64
+
+// ShardedOption configures a cache created by NewSharded.
+type ShardedOption[K comparable, V any] func(*shardedCache[K, V])
+
+// WithHasher overrides the default key hashing used to pick a shard. Use
+// this for key types NewSharded doesn't already know how to hash.
+func WithHasher[K comparable, V any](hasher Hasher[K]) ShardedOption[K, V] {
+	return func(c *shardedCache[K, V]) {
+		c.hasher = hasher
+	}
+}
+
+// WithShardOptions forwards Option[K,V]s - WithOnEvicted, WithOnExpired,
+// WithNegativeCacheTTL, ... - to the New call backing each shard, so a
+// sharded cache can use the same per-item callbacks and behaviour a plain
+// New cache can.
+func WithShardOptions[K comparable, V any](opts ...Option[K, V]) ShardedOption[K, V] {
+	return func(c *shardedCache[K, V]) {
+		c.shardOpts = opts
+	}
+}
+
+type shardedCache[K comparable, V any] struct {
+	shards    []Cache[K, V]
+	mask      uint64
+	hasher    Hasher[K]
+	shardOpts []Option[K, V]
+}
+
+// NewSharded returns a Cache[K,V] backed by shardCount independent caches,
+// so concurrent Set calls for different keys don't serialize on a single
+// mutex the way New's do. shardCount is rounded up to the next power of two
+// so shard selection is a cheap mask instead of a modulo. Use WithShardOptions
+// to forward New's Option[K,V]s (WithOnEvicted, WithOnExpired,
+// WithNegativeCacheTTL, ...) to every shard.
+//
+// defaultTTL - default duration after which the values will expire (<=0 for no expiration)
+//
+// cleanupInterval - cleanup interval for expired values (<=0 wihout cleanup)
+//
+// maxSize - maximum size across all shards combined (0 wihout limit); each
+// shard holds at most maxSize/shardCount entries but never less than 1, so
+// when maxSize < shardCount the combined total can reach shardCount
+//
+// shardCount - number of independent shards (rounded up to a power of two, minimum 1)
+func NewSharded[K comparable, V any](
+	defaultTTL time.Duration,
+	cleanupInterval time.Duration,
+	maxSize int,
+	shardCount int,
+	opts ...ShardedOption[K, V],
+) Cache[K, V] {
+	shardCount = nextPowerOfTwo(shardCount)
+
+	shardMaxSize := 0
+	if maxSize > 0 {
+		shardMaxSize = maxSize / shardCount
+		if shardMaxSize < 1 {
+			shardMaxSize = 1
+		}
+	}
+
+	c := &shardedCache[K, V]{
+		shards: make([]Cache[K, V], shardCount),
+		mask:   uint64(shardCount - 1),
+		hasher: defaultHasher[K](),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for i := range c.shards {
+		c.shards[i] = New[K, V](defaultTTL, cleanupInterval, shardMaxSize, c.shardOpts...)
+	}
+
+	return c
+}
+
+func nextPowerOfTwo(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// defaultHasher picks a built-in Hasher for the common key types; for
+// anything else it falls back to hashing the key's fmt representation,
+// which works but is slow - callers with a hot path should use WithHasher.
+func defaultHasher[K comparable]() Hasher[K] {
+	var zero K
+	switch any(zero).(type) {
+	case string:
+		return func(key K) uint64 {
+			return fnvHashString(any(key).(string))
+		}
+	case int:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(int))) }
+	case int8:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(int8))) }
+	case int16:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(int16))) }
+	case int32:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(int32))) }
+	case int64:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(int64))) }
+	case uint:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(uint))) }
+	case uint8:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(uint8))) }
+	case uint16:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(uint16))) }
+	case uint32:
+		return func(key K) uint64 { return mixUint64(uint64(any(key).(uint32))) }
+	case uint64:
+		return func(key K) uint64 { return mixUint64(any(key).(uint64)) }
+	default:
+		return func(key K) uint64 {
+			return fnvHashString(fmt.Sprintf("%v", key))
+		}
+	}
+}
+
+func fnvHashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mixUint64 spreads the bits of small integer keys (fnv-1a on a handful of
+// bytes clusters badly in the low bits that the shard mask uses).
+func mixUint64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+func (c *shardedCache[K, V]) shardFor(key K) Cache[K, V] {
+	return c.shards[c.hasher(key)&c.mask]
+}
+
+func (c *shardedCache[K, V]) Get(key K) (V, bool) {
+	return c.shardFor(key).Get(key)
+}
+
+func (c *shardedCache[K, V]) GetSafe(key K) (V, bool) {
+	return c.shardFor(key).GetSafe(key)
+}
+
+func (c *shardedCache[K, V]) GetValue(key K) V {
+	return c.shardFor(key).GetValue(key)
+}
+
+func (c *shardedCache[K, V]) Set(key K, value V) {
+	c.shardFor(key).Set(key, value)
+}
+
+func (c *shardedCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.shardFor(key).SetWithTTL(key, value, ttl)
+}
+
+func (c *shardedCache[K, V]) SetAll(m map[K]V) {
+	for i, batch := range c.splitByShard(m) {
+		if len(batch) > 0 {
+			c.shards[i].SetAll(batch)
+		}
+	}
+}
+
+func (c *shardedCache[K, V]) SetAllWithTTL(m map[K]V, ttl time.Duration) {
+	for i, batch := range c.splitByShard(m) {
+		if len(batch) > 0 {
+			c.shards[i].SetAllWithTTL(batch, ttl)
+		}
+	}
+}
+
+// splitByShard groups m's entries by the shard each key routes to, since a
+// batch can straddle multiple shards.
+func (c *shardedCache[K, V]) splitByShard(m map[K]V) []map[K]V {
+	batches := make([]map[K]V, len(c.shards))
+	for key, value := range m {
+		i := c.hasher(key) & c.mask
+		if batches[i] == nil {
+			batches[i] = make(map[K]V)
+		}
+		batches[i][key] = value
+	}
+	return batches
+}
+
+func (c *shardedCache[K, V]) GetOrLoad(key K, loader func(K) (V, time.Duration, error)) (V, error) {
+	//kazdy klic patri do jednoho shardu, takze coalescing delegujeme na nej
+	return c.shardFor(key).GetOrLoad(key, loader)
+}
+
+func (c *shardedCache[K, V]) GetOrLoadCtx(ctx context.Context, key K, loader func(context.Context, K) (V, time.Duration, error)) (V, error) {
+	return c.shardFor(key).GetOrLoadCtx(ctx, key, loader)
+}
+
+func (c *shardedCache[K, V]) Remove(key K) {
+	c.shardFor(key).Remove(key)
+}
+
+func (c *shardedCache[K, V]) IsEmpty() bool {
+	return c.Size() == 0
+}
+
+func (c *shardedCache[K, V]) Size() int {
+	size := 0
+	for _, shard := range c.shards {
+		size += shard.Size()
+	}
+	return size
+}
+
+func (c *shardedCache[K, V]) Clear() {
+	for _, shard := range c.shards {
+		shard.Clear()
+	}
+}
+
+func (c *shardedCache[K, V]) CleanExpired() {
+	for _, shard := range c.shards {
+		shard.CleanExpired()
+	}
+}
+
+func (c *shardedCache[K, V]) Close() {
+	for _, shard := range c.shards {
+		shard.Close()
+	}
+}
+
+func (c *shardedCache[K, V]) resetItems() {
+	for _, shard := range c.shards {
+		shard.resetItems()
+	}
+}
+
+// Stats aggregates every shard's counters into a single snapshot.
+func (c *shardedCache[K, V]) Stats() CacheStats {
+	stats := CacheStats{Evictions: make(map[Reason]int64, reasonCount)}
+	for _, shard := range c.shards {
+		s := shard.Stats()
+		stats.Hits += s.Hits
+		stats.Misses += s.Misses
+		stats.Sets += s.Sets
+		stats.Size += s.Size
+		for reason, count := range s.Evictions {
+			stats.Evictions[reason] += count
+		}
+	}
+	return stats
+}
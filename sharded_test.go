@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestSharded(t *testing.T) {
+	c := NewSharded[string, string](0, 0, 0, 4)
+
+	c.Set("key1", "val1")
+	c.Set("key2", "val2")
+	c.Set("key3", "val3")
+
+	if size := c.Size(); size != 3 {
+		t.Errorf("Size() = %v, want %v", size, 3)
+	}
+
+	if val1, _ := c.Get("key1"); val1 != "val1" {
+		t.Errorf("Get() = %v, want %v", val1, "val1")
+	}
+
+	c.SetAll(map[string]string{
+		"klic1": "val1",
+		"klic2": "val2",
+	})
+
+	if size := c.Size(); size != 5 {
+		t.Errorf("Size() = %v, want %v", size, 5)
+	}
+
+	c.Remove("key1")
+	if _, found := c.Get("key1"); found {
+		t.Errorf("Get(key1) found after Remove")
+	}
+
+	c.Clear()
+	if size := c.Size(); size != 0 {
+		t.Errorf("Size() = %v, want %v", size, 0)
+	}
+}
+
+func TestShardedPowerOfTwo(t *testing.T) {
+	cases := map[int]int{0: 1, 1: 1, 2: 2, 3: 4, 4: 4, 5: 8, 16: 16, 17: 32}
+	for in, want := range cases {
+		if got := nextPowerOfTwo(in); got != want {
+			t.Errorf("nextPowerOfTwo(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestShardedWithHasher(t *testing.T) {
+	//vsechno na jeden shard, jen abych overil ze se hasher pouzije
+	c := NewSharded[int, string](0, 0, 0, 4, WithHasher[int, string](func(int) uint64 { return 0 }))
+
+	c.Set(1, "a")
+	c.Set(2, "b")
+
+	if size := c.Size(); size != 2 {
+		t.Errorf("Size() = %v, want %v", size, 2)
+	}
+}
+
+func TestShardedWithShardOptions(t *testing.T) {
+	type evt struct {
+		key    string
+		reason Reason
+	}
+	var events []evt
+
+	c := NewSharded[string, string](0, 0, 1, 4,
+		WithShardOptions[string, string](
+			WithOnEvicted[string, string](func(key string, _ string, reason Reason) {
+				events = append(events, evt{key, reason})
+			}),
+		),
+	)
+
+	c.Remove("missing") //shard musi mit zaregistrovany callback i kdyz nic neodstrani
+	c.Set("key1", "val1")
+	c.Remove("key1")
+
+	if len(events) != 1 || events[0].key != "key1" || events[0].reason != ReasonManual {
+		t.Errorf("unexpected eviction events: %+v", events)
+	}
+}
+
+// BenchmarkSetUnsharded and BenchmarkShardedSetParallelN* demonstrate the
+// near-linear scaling with GOMAXPROCS that NewSharded exists for: unlike the
+// single-RWMutex cache, spreading writes across independent shards should
+// keep ns/op roughly flat (or falling) as shard count grows instead of
+// rising with contention the way BenchmarkSetUnsharded does.
+func BenchmarkSetUnsharded(b *testing.B) {
+	c := New[string, string](0, 0, 0)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Set("b"+strconv.Itoa(rand.Int()), "val")
+		}
+	})
+}
+
+func benchmarkShardedSetParallel(b *testing.B, shardCount int) {
+	c := NewSharded[string, string](0, 0, 0, shardCount)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Set("b"+strconv.Itoa(rand.Int()), "val")
+		}
+	})
+}
+
+func BenchmarkShardedSetParallelN4(b *testing.B) {
+	benchmarkShardedSetParallel(b, 4)
+}
+
+func BenchmarkShardedSetParallelN16(b *testing.B) {
+	benchmarkShardedSetParallel(b, 16)
+}
+
+func BenchmarkShardedSetParallelN64(b *testing.B) {
+	benchmarkShardedSetParallel(b, 64)
+}
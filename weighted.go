@@ -0,0 +1,503 @@
+package cache
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// weightedItem is a node of a weightedList, the eviction backbone shared by
+// the admission window and the main segment of a WeightedCache.
+type weightedItem[K comparable, V any] struct {
+	key        K
+	value      V
+	expiration int64
+	cost       int64
+	inWindow   bool
+	prev, next *weightedItem[K, V]
+}
+
+func (item *weightedItem[K, V]) isExpired(now int64) bool {
+	if item.expiration == 0 {
+		return false
+	}
+	return now > item.expiration
+}
+
+// weightedList is a plain cost-tracking doubly-linked LRU list, ordered
+// most- to least-recently-used. It has no mutex of its own - callers hold
+// the owning WeightedCache's mu.
+type weightedList[K comparable, V any] struct {
+	head, tail *weightedItem[K, V]
+	cost       int64
+	maxCost    int64
+}
+
+func (l *weightedList[K, V]) unlink(node *weightedItem[K, V]) {
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		l.head = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		l.tail = node.prev
+	}
+	node.prev, node.next = nil, nil
+	l.cost -= node.cost
+}
+
+func (l *weightedList[K, V]) pushFront(node *weightedItem[K, V]) {
+	node.prev = nil
+	node.next = l.head
+	if l.head != nil {
+		l.head.prev = node
+	}
+	l.head = node
+	if l.tail == nil {
+		l.tail = node
+	}
+	l.cost += node.cost
+}
+
+func (l *weightedList[K, V]) moveToFront(node *weightedItem[K, V]) {
+	if l.head == node {
+		return
+	}
+	cost := node.cost
+	l.unlink(node)
+	node.cost = cost
+	l.pushFront(node)
+}
+
+// WeightedStats reports WeightedCache hit/miss/admission counters. It is
+// updated with plain counters, not atomics, so callers should treat it as a
+// snapshot rather than a live view.
+type WeightedStats struct {
+	Hits, Misses   int64
+	Admits, Rejects int64
+}
+
+// WeightedCache is a cache that limits capacity by the sum of item
+// costs rather than item count, and guards against cache pollution with a
+// small W-TinyLFU admission policy: new items land in a small window LRU,
+// and spill into the main LRU while it has room; once it is full, only if a
+// Count-Min Sketch estimates them as at least as popular as the main
+// segment's current eviction candidate.
+//
+// It has every Cache[K,V] method except that Stats returns WeightedStats
+// instead of CacheStats, so it does not satisfy the Cache[K,V] interface.
+type WeightedCache[K comparable, V any] struct {
+	mu         sync.Mutex
+	items      map[K]*weightedItem[K, V]
+	window     weightedList[K, V]
+	main       weightedList[K, V]
+	costFn     func(K, V) int64
+	sketch     *countMinSketch
+	hasher     Hasher[K]
+	defaultTTL time.Duration
+	running    bool
+	done       chan struct{}
+	sf         singleflightGroup[K, V]
+
+	hits, misses, admits, rejects int64
+}
+
+// NewWeighted returns a new cache object that limits capacity by the sum of
+// costFn(key, value) over its entries instead of item count, and admits new
+// entries under a W-TinyLFU policy so a burst of one-hit-wonders can't evict
+// the cache's hot working set.
+//
+// defaultTTL - default duration after which the values will expire (<=0 for no expiration)
+//
+// cleanupInterval - cleanup interval for expired values (<=0 wihout cleanup)
+//
+// maxCost - maximum total cost across all entries (must be > 0)
+//
+// estimatedItems - expected number of entries resident at once, used to size
+// the admission policy's Count-Min Sketch; maxCost is a cost budget (bytes,
+// etc.), not an item count, so it can't be reused for this directly (must be > 0)
+func NewWeighted[K comparable, V any](
+	defaultTTL time.Duration,
+	cleanupInterval time.Duration,
+	maxCost int64,
+	estimatedItems int,
+	costFn func(K, V) int64,
+) *WeightedCache[K, V] {
+	windowMaxCost := maxCost / 100
+	if windowMaxCost < 1 {
+		windowMaxCost = 1
+	}
+	mainMaxCost := maxCost - windowMaxCost
+	if mainMaxCost < 1 {
+		mainMaxCost = 1
+	}
+	if estimatedItems < 1 {
+		estimatedItems = 1
+	}
+
+	c := &WeightedCache[K, V]{
+		items:      make(map[K]*weightedItem[K, V]),
+		costFn:     costFn,
+		sketch:     newCountMinSketch(uint64(estimatedItems)),
+		hasher:     defaultHasher[K](),
+		defaultTTL: defaultTTL,
+	}
+	c.window.maxCost = windowMaxCost
+	c.main.maxCost = mainMaxCost
+
+	runtime.SetFinalizer(c, func(c *WeightedCache[K, V]) {
+		c.Close()
+	})
+
+	//nastartovat uklid
+	if cleanupInterval > 0 {
+		ticker := time.NewTicker(cleanupInterval)
+
+		c.running = true
+		c.done = make(chan struct{})
+
+		go func() {
+			for {
+				select {
+				case <-c.done:
+					ticker.Stop()
+					return
+				case <-ticker.C:
+					c.CleanExpired()
+				}
+			}
+		}()
+	}
+
+	return c
+}
+
+// listFor returns the list a node currently lives in. Caller holds c.mu.
+func (c *WeightedCache[K, V]) listFor(node *weightedItem[K, V]) *weightedList[K, V] {
+	if node.inWindow {
+		return &c.window
+	}
+	return &c.main
+}
+
+func (c *WeightedCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	node, found := c.items[key]
+	if !found {
+		c.misses++
+		c.mu.Unlock()
+		return *new(V), false
+	}
+	c.hits++
+	c.sketch.Add(c.hasher(key))
+	c.listFor(node).moveToFront(node)
+	value := node.value
+	c.mu.Unlock()
+	return value, true
+}
+
+func (c *WeightedCache[K, V]) GetSafe(key K) (V, bool) {
+	c.mu.Lock()
+	node, found := c.items[key]
+	if !found || node.isExpired(time.Now().UnixNano()) {
+		c.misses++
+		c.mu.Unlock()
+		return *new(V), false
+	}
+	c.hits++
+	c.sketch.Add(c.hasher(key))
+	c.listFor(node).moveToFront(node)
+	value := node.value
+	c.mu.Unlock()
+	return value, true
+}
+
+func (c *WeightedCache[K, V]) GetValue(key K) V {
+	value, _ := c.Get(key)
+	return value
+}
+
+func (c *WeightedCache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+func (c *WeightedCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+	cost := c.costFn(key, value)
+	hash := c.hasher(key)
+
+	c.mu.Lock()
+
+	if node, found := c.items[key]; found {
+		list := c.listFor(node)
+		node.value = value
+		node.expiration = expiration
+		//unlink zatim s puvodnim cost (aby list.cost odecetl spravnou castku),
+		//teprve pak node.cost prepiseme a pushFront pricte tu novou - moveToFront
+		//to dela v jednom kroku jen kdyz se cost nemeni
+		list.unlink(node)
+		node.cost = cost
+		list.pushFront(node)
+		c.sketch.Add(hash)
+		c.evictOverCapacity(list)
+		c.mu.Unlock()
+		return
+	}
+
+	c.sketch.Add(hash)
+
+	node := &weightedItem[K, V]{key: key, value: value, expiration: expiration, cost: cost, inWindow: true}
+	c.items[key] = node
+	c.window.pushFront(node)
+
+	c.admitFromWindow()
+
+	c.mu.Unlock()
+}
+
+// evictOverCapacity drops least-recently-used entries from list (by plain
+// LRU, no admission contest) until it is back within its cost budget. It is
+// used when an in-place update grows an existing entry past its list's
+// limit. Caller holds c.mu.
+func (c *WeightedCache[K, V]) evictOverCapacity(list *weightedList[K, V]) {
+	for list.cost > list.maxCost && list.tail != nil {
+		victim := list.tail
+		list.unlink(victim)
+		delete(c.items, victim.key)
+	}
+}
+
+// admitFromWindow moves entries out of the admission window once it is over
+// budget, deciding via the Count-Min Sketch whether each one is popular
+// enough to displace the main segment's current LRU victim. Caller holds c.mu.
+func (c *WeightedCache[K, V]) admitFromWindow() {
+	for c.window.cost > c.window.maxCost && c.window.tail != nil {
+		candidate := c.window.tail
+		c.window.unlink(candidate)
+		candidate.inWindow = false
+
+		if c.main.cost+candidate.cost <= c.main.maxCost {
+			c.main.pushFront(candidate)
+			c.admits++
+			continue
+		}
+
+		victim := c.main.tail
+		if victim == nil {
+			c.main.pushFront(candidate)
+			c.admits++
+			continue
+		}
+
+		candFreq := c.sketch.Estimate(c.hasher(candidate.key))
+		victimFreq := c.sketch.Estimate(c.hasher(victim.key))
+		if candFreq >= victimFreq {
+			c.main.unlink(victim)
+			delete(c.items, victim.key)
+			c.main.pushFront(candidate)
+			c.admits++
+		} else {
+			delete(c.items, candidate.key)
+			c.rejects++
+		}
+	}
+}
+
+func (c *WeightedCache[K, V]) SetAll(m map[K]V) {
+	c.SetAllWithTTL(m, c.defaultTTL)
+}
+
+func (c *WeightedCache[K, V]) SetAllWithTTL(m map[K]V, ttl time.Duration) {
+	for key, value := range m {
+		c.SetWithTTL(key, value, ttl)
+	}
+}
+
+func (c *WeightedCache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	if node, found := c.items[key]; found {
+		c.listFor(node).unlink(node)
+		delete(c.items, key)
+	}
+	c.mu.Unlock()
+}
+
+func (c *WeightedCache[K, V]) IsEmpty() bool {
+	return c.Size() == 0
+}
+
+func (c *WeightedCache[K, V]) Size() int {
+	c.mu.Lock()
+	size := len(c.items)
+	c.mu.Unlock()
+	return size
+}
+
+func (c *WeightedCache[K, V]) Clear() {
+	c.mu.Lock()
+	c.resetItems()
+	c.mu.Unlock()
+}
+
+func (c *WeightedCache[K, V]) CleanExpired() {
+	now := time.Now().UnixNano()
+
+	c.mu.Lock()
+	var expired []*weightedItem[K, V]
+	for key, node := range c.items {
+		if node.isExpired(now) {
+			expired = append(expired, node)
+			delete(c.items, key)
+		}
+	}
+	for _, node := range expired {
+		c.listFor(node).unlink(node)
+	}
+	c.mu.Unlock()
+}
+
+func (c *WeightedCache[K, V]) Close() {
+	if c.running {
+		c.running = false
+		close(c.done)
+	}
+	c.items = make(map[K]*weightedItem[K, V])
+	c.window = weightedList[K, V]{maxCost: c.window.maxCost}
+	c.main = weightedList[K, V]{maxCost: c.main.maxCost}
+}
+
+func (c *WeightedCache[K, V]) resetItems() {
+	c.items = make(map[K]*weightedItem[K, V])
+	c.window = weightedList[K, V]{maxCost: c.window.maxCost}
+	c.main = weightedList[K, V]{maxCost: c.main.maxCost}
+}
+
+func (c *WeightedCache[K, V]) GetOrLoad(key K, loader func(K) (V, time.Duration, error)) (V, error) {
+	return c.sf.getOrLoad(key, loader, c.GetSafe, c.storeWithTTL)
+}
+
+func (c *WeightedCache[K, V]) GetOrLoadCtx(ctx context.Context, key K, loader func(context.Context, K) (V, time.Duration, error)) (V, error) {
+	return c.sf.getOrLoadCtx(ctx, key, loader, c.GetSafe, c.storeWithTTL)
+}
+
+// storeWithTTL writes a GetOrLoad result: ttl == 0 means "use the cache's
+// defaultTTL", matching the convention documented on the interface.
+func (c *WeightedCache[K, V]) storeWithTTL(key K, value V, ttl time.Duration) {
+	if ttl == 0 {
+		c.Set(key, value)
+		return
+	}
+	c.SetWithTTL(key, value, ttl)
+}
+
+// Stats returns a snapshot of the cache's hit/miss/admission counters.
+func (c *WeightedCache[K, V]) Stats() WeightedStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return WeightedStats{
+		Hits:    c.hits,
+		Misses:  c.misses,
+		Admits:  c.admits,
+		Rejects: c.rejects,
+	}
+}
+
+// countMinSketch is a 4-bit saturating-counter Count-Min Sketch used to
+// estimate how often a key has been seen recently, so NewWeighted's
+// admission policy can tell a hot key from a one-hit wonder.
+const cmDepth = 4
+
+type countMinSketch struct {
+	mu        sync.Mutex
+	width     uint64
+	rows      [cmDepth][]byte // each row packs width 4-bit counters, 2 per byte
+	additions uint64
+	resetAt   uint64
+}
+
+// newCountMinSketch sizes the sketch to about 10x estimatedItems counters
+// per row, per the standard Count-Min Sketch sizing rule of thumb.
+func newCountMinSketch(estimatedItems uint64) *countMinSketch {
+	width := uint64(nextPowerOfTwo(int(estimatedItems) * 10))
+	if width < 16 {
+		width = 16
+	}
+	s := &countMinSketch{width: width, resetAt: width * 10}
+	for i := range s.rows {
+		s.rows[i] = make([]byte, (width+1)/2)
+	}
+	return s
+}
+
+func (s *countMinSketch) indexes(h uint64) [cmDepth]uint64 {
+	var idx [cmDepth]uint64
+	for row := 0; row < cmDepth; row++ {
+		idx[row] = mixUint64(h^(uint64(row+1)*0x9E3779B97F4A7C15)) % s.width
+	}
+	return idx
+}
+
+func (s *countMinSketch) get(row int, i uint64) byte {
+	b := s.rows[row][i/2]
+	if i%2 == 0 {
+		return b & 0x0F
+	}
+	return b >> 4
+}
+
+func (s *countMinSketch) set(row int, i uint64, v byte) {
+	b := s.rows[row][i/2]
+	if i%2 == 0 {
+		s.rows[row][i/2] = (b & 0xF0) | v
+	} else {
+		s.rows[row][i/2] = (b & 0x0F) | (v << 4)
+	}
+}
+
+func (s *countMinSketch) Add(h uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for row, i := range s.indexes(h) {
+		if v := s.get(row, i); v < 15 {
+			s.set(row, i, v+1)
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.halve()
+	}
+}
+
+func (s *countMinSketch) Estimate(h uint64) byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	min := byte(15)
+	for row, i := range s.indexes(h) {
+		if v := s.get(row, i); v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// halve divides every counter by two, the classic Count-Min Sketch "reset"
+// that keeps old frequency estimates from dominating forever. Caller holds s.mu.
+func (s *countMinSketch) halve() {
+	for r := range s.rows {
+		row := s.rows[r]
+		for i := range row {
+			lo := row[i] & 0x0F
+			hi := row[i] >> 4
+			row[i] = ((hi >> 1) << 4) | (lo >> 1)
+		}
+	}
+	s.additions /= 2
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeightedCapacity(t *testing.T) {
+	c := NewWeighted[string, string](0, 0, 10, 10, func(string, string) int64 { return 1 })
+
+	for i := 0; i < 20; i++ {
+		c.Set(string(rune('a'+i)), "val")
+	}
+
+	if size := c.Size(); size > 10 {
+		t.Errorf("Size() = %v, want <= %v", size, 10)
+	}
+}
+
+func TestWeightedAdmission(t *testing.T) {
+	cost := func(string, string) int64 { return 1 }
+	c := NewWeighted[string, string](0, 0, 4, 4, cost)
+
+	//"hot" se cte porad dokola, aby sketch videl vysokou frekvenci
+	c.Set("hot", "val")
+	for i := 0; i < 20; i++ {
+		c.Get("hot")
+	}
+
+	//jednorazove klice, co by mely hot vytlacit jen kdyz nebude fungovat admission
+	for i := 0; i < 50; i++ {
+		c.Set(string(rune('a'+(i%26))), "val")
+	}
+
+	if _, found := c.GetSafe("hot"); !found {
+		t.Errorf("GetSafe(hot) not found, admission policy failed to protect a hot key")
+	}
+
+	stats := c.Stats()
+	if stats.Rejects == 0 {
+		t.Errorf("Stats().Rejects = 0, want some one-hit-wonders rejected")
+	}
+}
+
+func TestWeightedListMoveToFrontCostUpdate(t *testing.T) {
+	list := &weightedList[string, string]{maxCost: 1000}
+	node := &weightedItem[string, string]{key: "a", cost: 1}
+	list.pushFront(node)
+
+	//update branch ze SetWithTTL: unlink() se spusti jeste se starou cenou,
+	//teprve pak se node.cost prepise a pushFront() pricte tu novou
+	list.unlink(node)
+	node.cost = 1000
+	list.pushFront(node)
+
+	if list.cost != 1000 {
+		t.Errorf("list.cost = %v, want 1000 (old cost of 1 must not linger)", list.cost)
+	}
+
+	list.unlink(node)
+	node.cost = 1
+	list.pushFront(node)
+
+	if list.cost != 1 {
+		t.Errorf("list.cost after shrink = %v, want 1", list.cost)
+	}
+}
+
+func TestWeightedSetCostUpdateTriggersEviction(t *testing.T) {
+	costs := map[string]int64{"a": 1}
+	cost := func(key string, _ string) int64 { return costs[key] }
+	c := NewWeighted[string, string](0, 0, 10, 10, cost)
+
+	c.Set("a", "val")
+	if _, found := c.GetSafe("a"); !found {
+		t.Fatalf("GetSafe(a) not found right after Set")
+	}
+
+	//"a" zdraznim daleko nad maxCost - pokud by list.cost dal nezapocital tu
+	//novou cenu (puvodni bug), evictOverCapacity by to nikdy neodhalilo
+	costs["a"] = 1000
+	c.Set("a", "val-big")
+
+	if _, found := c.GetSafe("a"); found {
+		t.Errorf("GetSafe(a) found after growing its cost past maxCost, want evicted")
+	}
+}
+
+func TestWeightedGetOrLoad(t *testing.T) {
+	c := NewWeighted[string, int](0, 0, 100, 100, func(string, int) int64 { return 1 })
+
+	val, err := c.GetOrLoad("key", func(key string) (int, time.Duration, error) {
+		return len(key), 0, nil
+	})
+	if err != nil || val != 3 {
+		t.Errorf("GetOrLoad() = %v, %v, want 3, nil", val, err)
+	}
+}